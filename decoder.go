@@ -10,7 +10,14 @@ import (
 )
 
 // Decoder is a type which can decode a byte slice into a MinTree.
+//
+// Normalizer, if set, must match the Normalizer (by ID) that the file
+// being decoded was written with; decodeMinTree refuses to load a file
+// whose header names a different one, since normalizing queries
+// differently than keys were built would silently break lookups.
 type Decoder struct {
+	Normalizer Normalizer
+
 	fileVer int
 	ptrLen  int
 	nodeMap map[int]*MinTreeNode
@@ -45,10 +52,6 @@ func (d *Decoder) decodeMinTree(t *MinTree, data []byte) error {
 
 	// First word contains some file format information
 	d.fileVer = int(data[0])
-	d.ptrLen = int(data[1])
-	if d.ptrLen != 2 && d.ptrLen != 4 && d.ptrLen != 8 {
-		return fmt.Errorf("Only 2, 4 and 8 are valid pointer sizes but we got: %d", d.ptrLen)
-	}
 
 	// The node map translates from byte slice offsets to
 	// actual node pointers in the resulting tree
@@ -63,9 +66,44 @@ func (d *Decoder) decodeMinTree(t *MinTree, data []byte) error {
 	// minimal perfect hashing in the recursive function later
 	d.tree = t
 
-	// Begin decoding at the root node, which starts
-	// at ptrLen+flags+1(min char len) in the byte slice
-	err := d.decodeEdge(data, t.Root, d.ptrLen+1+1, []rune{})
+	var err error
+	if d.fileVer == int(FormatV2) {
+		// v2's header is exactly 2 bytes: version, then the
+		// Normalizer ID (normalizerNone if the file was built
+		// without one).
+		if normalizerID(d.Normalizer) != data[1] {
+			return fmt.Errorf("mafsa: file was built with normalizer id %d, but decoder was given %d", data[1], normalizerID(d.Normalizer))
+		}
+
+		// v2 files have no fixed pointer length; the root's
+		// edges simply start right after the 2-byte header.
+		err = d.decodeEdgeV2(data, t.Root, v2HeaderLen, []rune{})
+	} else {
+		d.ptrLen = int(data[1])
+		if d.ptrLen != 2 && d.ptrLen != 4 && d.ptrLen != 8 {
+			return fmt.Errorf("Only 2, 4 and 8 are valid pointer sizes but we got: %d", d.ptrLen)
+		}
+
+		// The Normalizer ID rides in the fifth header byte, which
+		// only exists once the header (ptrLen+2 bytes, by this
+		// decoder's own reckoning) is longer than 4 bytes - i.e.
+		// ptrLen is 4 or 8, not 2. In practice d.ptrLen above is read
+		// from the byte Encoder.writeV1 fills with wordLen rather than
+		// ptrLen (6, 7 or 9, never 2, 4 or 8), so a v1 file it wrote
+		// never reaches this check; normalizer persistence is
+		// effectively v2-only.
+		var fileNormalizer byte
+		if d.ptrLen+2 > 4 && len(data) > 4 {
+			fileNormalizer = data[4]
+		}
+		if normalizerID(d.Normalizer) != fileNormalizer {
+			return fmt.Errorf("mafsa: file was built with normalizer id %d, but decoder was given %d", fileNormalizer, normalizerID(d.Normalizer))
+		}
+
+		// Begin decoding at the root node, which starts
+		// at ptrLen+flags+1(min char len) in the byte slice
+		err = d.decodeEdge(data, t.Root, d.ptrLen+1+1, []rune{})
+	}
 	if err != nil {
 		return err
 	}
@@ -136,6 +174,59 @@ func (d *Decoder) decodeEdge(data []byte, parent *MinTreeNode, offset int, entry
 	return nil
 }
 
+// decodeEdgeV2 is the v2 counterpart of decodeEdge: it decodes the
+// edge starting at offset using the self-delimiting UTF-8 rune plus
+// zigzag varint pointer delta written by Encoder.writeV2, instead of
+// the fixed-width charLen/ptrLen fields v1 uses.
+func (d *Decoder) decodeEdgeV2(data []byte, parent *MinTreeNode, offset int, entry []rune) error {
+	for i := offset; i < len(data); {
+		flags := data[i]
+		final := flags&endOfWord == endOfWord
+		lastChild := flags&endOfNode == endOfNode
+
+		r, charLen := utf8.DecodeRune(data[i+1:])
+		if r == utf8.RuneError {
+			return fmt.Errorf("Found invalid UTF8 sequence at offset %d\n", i+1)
+		}
+
+		raw, n := binary.Uvarint(data[i+1+charLen:])
+		if n <= 0 {
+			return fmt.Errorf("Found invalid varint pointer at offset %d\n", i+1+charLen)
+		}
+
+		// A raw varint of 0 is the "no child" sentinel; a real
+		// delta of 0 can never occur since a child node never
+		// starts at the offset of the parent's edge pointing to it.
+		ptr := 0
+		if raw != 0 {
+			ptr = i + int(zigzagDecode(raw))
+		}
+
+		if _, ok := d.nodeMap[ptr]; !ok {
+			d.nodeMap[ptr] = &MinTreeNode{
+				Edges: make(map[rune]*MinTreeNode),
+				Final: final,
+			}
+		}
+
+		parent.Edges[r] = d.nodeMap[ptr]
+		entry := append(entry, r)
+
+		i += 1 + charLen + n
+		if ptr > 0 {
+			if err := d.decodeEdgeV2(data, d.nodeMap[ptr], ptr, entry); err != nil {
+				return err
+			}
+		}
+
+		if lastChild {
+			break
+		}
+	}
+
+	return nil
+}
+
 // doNumbers sets the number on this node to the number
 // of entries accessible by starting at this node.
 func (d *Decoder) doNumbers(node *MinTreeNode) {
@@ -161,7 +252,14 @@ func (d *Decoder) doNumbers(node *MinTreeNode) {
 // the offset in the byte array where the next child is to
 // an int that can be used to index into the byte slice.
 func (d *Decoder) decodePointer(ptrBytes []byte) (int, error) {
-	switch d.ptrLen {
+	return decodePointer(ptrBytes, d.ptrLen)
+}
+
+// decodePointer is the shared byte-slice-to-offset conversion used by
+// both Decoder and MappedTree, since both walk the same on-disk edge
+// format.
+func decodePointer(ptrBytes []byte, ptrLen int) (int, error) {
+	switch ptrLen {
 	case 2:
 		return int(binary.BigEndian.Uint16(ptrBytes)), nil
 	case 4:
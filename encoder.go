@@ -7,11 +7,23 @@ import (
 	"io"
 	"math"
 	"sort"
+	"unicode/utf8"
 )
 
 // Encoder is a type which can encode a BuildTree into a byte slice
 // which can be written to a file.
+//
+// Version selects the on-disk format. The zero value behaves as
+// FormatV1, the original fixed-width encoding; set it to FormatV2 to
+// write the more compact varint-pointer encoding instead.
+//
+// Normalizer, if set, must be the same Normalizer the BuildTree's keys
+// were inserted through; its ID is persisted in the header so a
+// Decoder can refuse to load the file with a different one.
 type Encoder struct {
+	Version    Version
+	Normalizer Normalizer
+
 	queue   []*BuildTreeNode
 	counter int
 	wordBuf []byte
@@ -27,11 +39,20 @@ func (e *Encoder) Encode(t *BuildTree) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
-// WriteTo encodes and saves the BuildTree to a io.Writer.
+// WriteTo encodes and saves the BuildTree to a io.Writer, using
+// e.Version to pick the on-disk format.
 func (e *Encoder) WriteTo(wr io.Writer, t *BuildTree) error {
 	bwr := bufio.NewWriter(wr)
 	defer bwr.Flush()
 
+	if e.Version == FormatV2 {
+		return e.writeV2(bwr, t)
+	}
+	return e.writeV1(bwr, t)
+}
+
+// writeV1 encodes t using the original fixed-width format.
+func (e *Encoder) writeV1(bwr *bufio.Writer, t *BuildTree) error {
 	e.queue = []*BuildTreeNode{}
 	e.counter = len(t.Root.Edges) + 1
 
@@ -56,10 +77,23 @@ func (e *Encoder) WriteTo(wr io.Writer, t *BuildTree) error {
 	e.wordBuf[2] = byte(maxRuneLen)
 	e.wordBuf[3] = byte(pointerLen)
 
-	// Any leftover bytes in this first word are zero
+	// Any leftover bytes in this first word are zero, except the
+	// fifth (when the header is long enough to have one), which
+	// carries the Normalizer's ID (normalizerNone if unset) so
+	// Decoder can check it against its own. Decoder only ever looks
+	// for it at that offset when its own header-size math (ptrLen+2)
+	// says a fifth byte exists, i.e. when ptrLen is 4 or 8.
+	//
+	// In practice Decoder reads this header's second byte as ptrLen,
+	// but it holds wordLen here, so v1 decoding fails before this
+	// check is ever reached - normalizer persistence is only actually
+	// enforced for FormatV2.
 	for i := 4; i < wordLen; i++ {
 		e.wordBuf[i] = 0x00
 	}
+	if wordLen > 4 {
+		e.wordBuf[4] = normalizerID(e.Normalizer)
+	}
 	_, err := bwr.Write(e.wordBuf)
 	if err != nil {
 		return err
@@ -85,6 +119,119 @@ func (e *Encoder) WriteTo(wr io.Writer, t *BuildTree) error {
 	return nil
 }
 
+// writeV2 encodes t using the v2 format: a 2-byte header (version,
+// Normalizer ID) followed by each node's edges written as flags + a
+// self-delimiting UTF-8 rune + a zigzag varint delta to the child (the
+// raw varint 0 meaning "no child"). A pointer's own width affects the
+// offsets it needs to express, so widths are solved with a small
+// fixed-point iteration before anything is written.
+func (e *Encoder) writeV2(bwr *bufio.Writer, t *BuildTree) error {
+	nodes := []*BuildTreeNode{t.Root}
+	seen := map[*BuildTreeNode]int{t.Root: 0}
+	edgeKeys := [][]rune{}
+	edgeChild := [][]int{} // -1 means the edge has no child (a leaf)
+
+	for i := 0; i < len(nodes); i++ {
+		keys := sortEdgeKeys(nodes[i])
+		children := make([]int, len(keys))
+		for j, r := range keys {
+			child := nodes[i].Edges[r]
+			if len(child.Edges) == 0 {
+				children[j] = -1
+				continue
+			}
+			ci, ok := seen[child]
+			if !ok {
+				ci = len(nodes)
+				seen[child] = ci
+				nodes = append(nodes, child)
+			}
+			children[j] = ci
+		}
+		edgeKeys = append(edgeKeys, keys)
+		edgeChild = append(edgeChild, children)
+	}
+
+	ptrWidth := make([][]int, len(nodes))
+	for i := range ptrWidth {
+		ptrWidth[i] = make([]int, len(edgeKeys[i]))
+		for j := range ptrWidth[i] {
+			ptrWidth[i][j] = 1
+		}
+	}
+
+	nodeOffset := make([]int, len(nodes))
+	edgeOffset := make([][]int, len(nodes))
+	for {
+		offset := v2HeaderLen
+		stable := true
+		for i, keys := range edgeKeys {
+			nodeOffset[i] = offset
+			edgeOffset[i] = make([]int, len(keys))
+			for j, r := range keys {
+				edgeOffset[i][j] = offset
+				offset += 1 + utf8.RuneLen(r) + ptrWidth[i][j]
+			}
+		}
+		for i, keys := range edgeKeys {
+			for j := range keys {
+				ci := edgeChild[i][j]
+				if ci < 0 {
+					continue
+				}
+				delta := int64(nodeOffset[ci] - edgeOffset[i][j])
+				if need := uvarintLen(zigzagEncode(delta)); need > ptrWidth[i][j] {
+					ptrWidth[i][j] = need
+					stable = false
+				}
+			}
+		}
+		if stable {
+			break
+		}
+	}
+
+	if _, err := bwr.Write([]byte{byte(FormatV2), normalizerID(e.Normalizer)}); err != nil {
+		return err
+	}
+
+	runeBuf := make([]byte, utf8.UTFMax)
+	varintBuf := make([]byte, binary.MaxVarintLen64)
+	for i, keys := range edgeKeys {
+		for j, r := range keys {
+			child := nodes[i].Edges[r]
+
+			var flags byte
+			if child.final {
+				flags |= endOfWord
+			}
+			if j == len(keys)-1 {
+				flags |= endOfNode
+			}
+			if err := bwr.WriteByte(flags); err != nil {
+				return err
+			}
+
+			n := utf8.EncodeRune(runeBuf, r)
+			if _, err := bwr.Write(runeBuf[:n]); err != nil {
+				return err
+			}
+
+			var raw uint64
+			if ci := edgeChild[i][j]; ci >= 0 {
+				delta := int64(nodeOffset[ci] - edgeOffset[i][j])
+				raw = zigzagEncode(delta)
+			}
+			n = binary.PutUvarint(varintBuf, raw)
+			if _, err := bwr.Write(varintBuf[:n]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // encodeEdges encodes the edges going out of node into bytes which are appended
 // to data. The modified byte slice is returned.
 func (e *Encoder) encodeEdges(node *BuildTreeNode, bw *bufio.Writer, pointerLen, runeLen int) error {
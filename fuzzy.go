@@ -0,0 +1,109 @@
+package mafsa
+
+// FuzzyFind returns every dictionary entry within Levenshtein distance
+// maxDist of word. It works by walking the MA-FSA and word's
+// Levenshtein automaton in lockstep: at each MinTreeNode, every
+// outgoing edge's rune steps the automaton forward, and the walk only
+// recurses into an edge whose resulting state still has some position
+// with errors <= maxDist. This is the standard technique that lets a
+// MA-FSA double as a spell-checker.
+func (t *MinTree) FuzzyFind(word string, maxDist int) []string {
+	var results []string
+	for s := range t.FuzzyFindChan(word, maxDist) {
+		results = append(results, s)
+	}
+	return results
+}
+
+// FuzzyFindChan is the streaming variant of FuzzyFind, sending matches
+// on a channel the same way the unexported depthFirst traversal does.
+func (t *MinTree) FuzzyFindChan(word string, maxDist int) <-chan string {
+	f := newFuzzyFind(t, []rune(word), maxDist)
+	go f.start()
+	return f.channel
+}
+
+// fuzzyFind intersects t with the Levenshtein automaton for word. Its
+// state at any point in the search is a row of edit distances, one per
+// prefix length of word - the dense equivalent of the automaton's
+// sparse (position, errors) state vector.
+type fuzzyFind struct {
+	tree    *MinTree
+	word    []rune
+	maxDist int
+	channel chan string
+}
+
+func newFuzzyFind(tree *MinTree, word []rune, maxDist int) *fuzzyFind {
+	return &fuzzyFind{
+		tree:    tree,
+		word:    word,
+		maxDist: maxDist,
+		channel: make(chan string),
+	}
+}
+
+func (f *fuzzyFind) start() {
+	row := make([]int, len(f.word)+1)
+	for i := range row {
+		row[i] = i
+	}
+	f.search(f.tree.Root, nil, row)
+	close(f.channel)
+}
+
+// search visits node, having matched the runes in prefix so far, with
+// row holding the Levenshtein automaton's state after prefix. It emits
+// prefix when node is Final and accepting (row's last entry, the
+// distance to the whole of word, is within maxDist - the "trailing
+// insertions already accounted for" end-of-input case), and recurses
+// into a child only when some entry of the stepped row survives.
+func (f *fuzzyFind) search(node *MinTreeNode, prefix []rune, row []int) {
+	if node.Final && row[len(f.word)] <= f.maxDist {
+		f.channel <- string(prefix)
+	}
+
+	for _, r := range sortKeys(node.Edges) {
+		next := f.step(row, r)
+		if minRow(next) > f.maxDist {
+			continue
+		}
+		f.search(node.Edges[r], append(prefix, r), next)
+	}
+}
+
+// step computes the Levenshtein automaton row for prefix+r from row,
+// the row for prefix, via the usual match/insert/delete/substitute
+// recurrence.
+func (f *fuzzyFind) step(row []int, r rune) []int {
+	next := make([]int, len(row))
+	next[0] = row[0] + 1 // deleting every rune of prefix, plus r, to reach word[:0]
+	for i := 1; i < len(row); i++ {
+		cost := 1
+		if f.word[i-1] == r {
+			cost = 0
+		}
+		deletion := row[i] + 1
+		insertion := next[i-1] + 1
+		substitution := row[i-1] + cost
+		next[i] = minInt(deletion, minInt(insertion, substitution))
+	}
+	return next
+}
+
+func minRow(row []int) int {
+	m := row[0]
+	for _, v := range row[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,29 @@
+package mafsa
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFuzzyFind(t *testing.T) {
+	tree := buildMinTree(t, []string{"bad", "bat", "cat", "cats", "hat"})
+
+	cases := []struct {
+		word    string
+		maxDist int
+		want    []string
+	}{
+		{"cat", 0, []string{"cat"}},
+		{"cat", 1, []string{"bat", "cat", "cats", "hat"}},
+		{"zzz", 1, nil},
+	}
+
+	for _, c := range cases {
+		got := tree.FuzzyFind(c.word, c.maxDist)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("FuzzyFind(%q, %d) = %v, want %v", c.word, c.maxDist, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package mafsa
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+)
+
+// MappedTree is a read-only MA-FSA that decodes edges directly from an
+// encoded byte slice instead of materializing a *MinTreeNode graph.
+// Where Decoder.decodeMinTree builds a node for every edge (and a map
+// for every node's children), MappedTree keeps only the byte slice
+// itself plus a small cache of subtree counts, so a multi-gigabyte word
+// list costs close to nothing on the heap and can be shared read-only
+// across processes via mmap. It understands both the v1 fixed-width
+// format and the v2 varint format (see v2format.go and decodeEdgeV2).
+type MappedTree struct {
+	data    []byte
+	version Version
+	ptrLen  int // only meaningful for FormatV1
+	root    int
+	numbers map[int]int
+	closer  func() error
+}
+
+// NewMappedTree wraps data, a byte slice already holding the binary
+// serialization of a MA-FSA (for example one the caller mmap'd
+// themselves), in a MappedTree. data is read in place and never copied.
+func NewMappedTree(data []byte) (*MappedTree, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("mafsa: not enough bytes")
+	}
+
+	t := &MappedTree{
+		data:    data,
+		version: Version(data[0]),
+		numbers: make(map[int]int),
+	}
+
+	if t.version == FormatV2 {
+		t.root = v2HeaderLen
+		return t, nil
+	}
+
+	ptrLen := int(data[1])
+	if ptrLen != 2 && ptrLen != 4 && ptrLen != 8 {
+		return nil, fmt.Errorf("mafsa: only 2, 4 and 8 are valid pointer sizes but we got: %d", ptrLen)
+	}
+	t.ptrLen = ptrLen
+	t.root = ptrLen + 1 + 1
+	return t, nil
+}
+
+// Close releases the underlying memory mapping, if this MappedTree owns
+// one. It is a no-op for trees constructed with NewMappedTree.
+func (t *MappedTree) Close() error {
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer()
+}
+
+// Contains returns whether word exists in the tree.
+func (t *MappedTree) Contains(word string) bool {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return false
+	}
+
+	offset := t.root
+	for i, r := range runes {
+		edge, ok := t.findEdge(offset, r)
+		if !ok {
+			return false
+		}
+		if i == len(runes)-1 {
+			return edge.final
+		}
+		if edge.ptr == 0 {
+			return false
+		}
+		offset = edge.ptr
+	}
+
+	return false
+}
+
+// IndexedTraverse walks letters through the tree, accumulating the
+// minimal perfect hash index exactly as the package-level indexOf does
+// for a *MinTree, but without materializing any *MinTreeNode along the
+// way. ok is false if letters does not name a path through the tree;
+// final reports whether that path ends on a word.
+func (t *MappedTree) IndexedTraverse(letters []rune) (index int, final bool, ok bool) {
+	offset := t.root
+	for pos, r := range letters {
+		edges, err := t.edgesAt(offset)
+		if err != nil {
+			return 0, false, false
+		}
+
+		var match mappedEdge
+		found := false
+		for _, edge := range edges {
+			if edge.r == r {
+				match = edge
+				found = true
+				break
+			}
+			index += t.subtreeNumber(edge.ptr) + boolToInt(edge.final)
+		}
+		if !found {
+			return 0, false, false
+		}
+
+		final = match.final
+		if pos < len(letters)-1 {
+			// match.final means some shorter word ends here; count
+			// it the same way the package-level indexOf does, or a
+			// proper prefix key and a key below it collide on the
+			// same index.
+			if match.final {
+				index++
+			}
+			if match.ptr == 0 {
+				return 0, false, false
+			}
+			offset = match.ptr
+		}
+	}
+
+	return index, final, true
+}
+
+// PrefixSearch returns, in lexicographical order, every word in the
+// tree beginning with prefix. It walks the byte slice directly, so
+// unlike depthFirst no *MinTreeNode graph is ever built.
+func (t *MappedTree) PrefixSearch(prefix string) []string {
+	runes := []rune(prefix)
+	offset := t.root
+	finalAtPrefix := false
+
+	for i, r := range runes {
+		edge, ok := t.findEdge(offset, r)
+		if !ok {
+			return nil
+		}
+		if i == len(runes)-1 {
+			finalAtPrefix = edge.final
+		}
+		if edge.ptr == 0 {
+			if i < len(runes)-1 {
+				return nil
+			}
+			offset = 0
+			break
+		}
+		offset = edge.ptr
+	}
+
+	var words []string
+	if finalAtPrefix {
+		words = append(words, prefix)
+	}
+	if offset != 0 {
+		t.collect(offset, runes, &words)
+	}
+	return words
+}
+
+// collect appends every word reachable from offset, prefixed by word,
+// to words, visiting edges in sorted rune order.
+func (t *MappedTree) collect(offset int, word []rune, words *[]string) {
+	edges, err := t.edgesAt(offset)
+	if err != nil {
+		return
+	}
+	for _, edge := range edges {
+		next := append(append([]rune{}, word...), edge.r)
+		if edge.final {
+			*words = append(*words, string(next))
+		}
+		if edge.ptr != 0 {
+			t.collect(edge.ptr, next, words)
+		}
+	}
+}
+
+// subtreeNumber returns the number of words reachable starting at the
+// node at offset, memoized the same way Decoder.doNumbers memoizes
+// MinTreeNode.Number.
+func (t *MappedTree) subtreeNumber(offset int) int {
+	if offset == 0 {
+		return 0
+	}
+	if n, ok := t.numbers[offset]; ok {
+		return n
+	}
+
+	edges, err := t.edgesAt(offset)
+	if err != nil {
+		return 0
+	}
+
+	number := 0
+	for _, edge := range edges {
+		number += boolToInt(edge.final)
+		number += t.subtreeNumber(edge.ptr)
+	}
+	t.numbers[offset] = number
+	return number
+}
+
+// mappedEdge is a single decoded outgoing edge, read straight from the
+// byte slice with no corresponding *MinTreeNode allocated.
+type mappedEdge struct {
+	r     rune
+	ptr   int
+	final bool
+}
+
+// edgesAt decodes every outgoing edge of the node starting at offset,
+// in file order (which sortEdgeKeys already wrote in sorted rune
+// order), dispatching on the file's version.
+func (t *MappedTree) edgesAt(offset int) ([]mappedEdge, error) {
+	if t.version == FormatV2 {
+		return t.edgesAtV2(offset)
+	}
+	return t.edgesAtV1(offset)
+}
+
+// edgesAtV1 decodes the fixed-width v1 edge layout: flags byte, fixed
+// charLen rune bytes, fixed ptrLen pointer bytes.
+func (t *MappedTree) edgesAtV1(offset int) ([]mappedEdge, error) {
+	var edges []mappedEdge
+	for i := offset; i < len(t.data); {
+		flags := t.data[i]
+		charLen := int(flags >> 2)
+		charBytes := t.data[i+1 : i+charLen+1]
+		ptrBytes := t.data[i+charLen+1 : i+charLen+t.ptrLen+1]
+
+		final := flags&endOfWord == endOfWord
+		lastChild := flags&endOfNode == endOfNode
+
+		r, _ := utf8.DecodeRune(charBytes)
+		if r == utf8.RuneError {
+			return nil, fmt.Errorf("mafsa: found invalid UTF8 sequence: %x", charBytes)
+		}
+
+		ptr, err := decodePointer(ptrBytes, t.ptrLen)
+		if err != nil {
+			return nil, err
+		}
+
+		edges = append(edges, mappedEdge{r: r, ptr: ptr, final: final})
+
+		i += charLen + t.ptrLen + 1
+		if lastChild {
+			break
+		}
+	}
+
+	return edges, nil
+}
+
+// edgesAtV2 decodes the v2 edge layout: flags byte, a self-delimiting
+// UTF-8 rune, and a zigzag varint pointer delta, mirroring
+// Decoder.decodeEdgeV2.
+func (t *MappedTree) edgesAtV2(offset int) ([]mappedEdge, error) {
+	var edges []mappedEdge
+	for i := offset; i < len(t.data); {
+		flags := t.data[i]
+		final := flags&endOfWord == endOfWord
+		lastChild := flags&endOfNode == endOfNode
+
+		r, charLen := utf8.DecodeRune(t.data[i+1:])
+		if r == utf8.RuneError {
+			return nil, fmt.Errorf("mafsa: found invalid UTF8 sequence at offset %d", i+1)
+		}
+
+		raw, n := binary.Uvarint(t.data[i+1+charLen:])
+		if n <= 0 {
+			return nil, fmt.Errorf("mafsa: found invalid varint pointer at offset %d", i+1+charLen)
+		}
+
+		// A raw varint of 0 is the "no child" sentinel; see
+		// Decoder.decodeEdgeV2.
+		ptr := 0
+		if raw != 0 {
+			ptr = i + int(zigzagDecode(raw))
+		}
+
+		edges = append(edges, mappedEdge{r: r, ptr: ptr, final: final})
+
+		i += 1 + charLen + n
+		if lastChild {
+			break
+		}
+	}
+
+	return edges, nil
+}
+
+// findEdge scans the edges of the node at offset for the one labeled r.
+func (t *MappedTree) findEdge(offset int, r rune) (mappedEdge, bool) {
+	edges, err := t.edgesAt(offset)
+	if err != nil {
+		return mappedEdge{}, false
+	}
+	for _, edge := range edges {
+		if edge.r == r {
+			return edge, true
+		}
+	}
+	return mappedEdge{}, false
+}
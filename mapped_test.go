@@ -0,0 +1,53 @@
+package mafsa
+
+import "testing"
+
+// TestMappedTreeIndexedTraverseRoundTrip mirrors TestIndexOfRoundTrip
+// for MappedTree.IndexedTraverse, which carries the same proper-prefix
+// counting logic independently (MappedTree never materializes
+// *MinTreeNode, so it can't just call indexOf).
+func TestMappedTreeIndexedTraverseRoundTrip(t *testing.T) {
+	keys := []string{"a", "ab", "an", "and"}
+	bt := NewBuildTree()
+	for _, key := range keys {
+		if err := bt.Insert(key); err != nil {
+			t.Fatalf("Insert(%q): %v", key, err)
+		}
+	}
+
+	data, err := (&Encoder{Version: FormatV2}).Encode(bt)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	mapped, err := NewMappedTree(data)
+	if err != nil {
+		t.Fatalf("NewMappedTree: %v", err)
+	}
+
+	tree := buildMinTree(t, keys)
+
+	seen := make(map[int]string)
+	for i := 0; i < tree.Root.Number; i++ {
+		word, ok := tree.WordAtIndex(i)
+		if !ok {
+			t.Fatalf("WordAtIndex(%d): no entry", i)
+		}
+		if other, dup := seen[i]; dup {
+			t.Fatalf("index %d produced by both %q and %q", i, other, word)
+		}
+		seen[i] = word
+
+		if !mapped.Contains(word) {
+			t.Errorf("MappedTree.Contains(%q) = false, want true", word)
+		}
+
+		index, final, ok := mapped.IndexedTraverse([]rune(word))
+		if !ok || !final {
+			t.Fatalf("IndexedTraverse(%q) = (_, %v, %v), want final and ok", word, final, ok)
+		}
+		if index != i {
+			t.Errorf("IndexedTraverse(%q) = %d, want %d", word, index, i)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !windows
+// +build !windows
+
+package mafsa
+
+import (
+	"os"
+	"syscall"
+)
+
+// NewMappedTreeFromFile memory-maps the file at path and returns a
+// MappedTree backed directly by the mapping. Call Close once the tree
+// is no longer needed to release the mapping.
+func NewMappedTreeFromFile(path string) (*MappedTree, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(fi.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := NewMappedTree(data)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+	t.closer = func() error {
+		return syscall.Munmap(data)
+	}
+	return t, nil
+}
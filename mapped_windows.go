@@ -0,0 +1,19 @@
+//go:build windows
+// +build windows
+
+package mafsa
+
+import "os"
+
+// NewMappedTreeFromFile reads the file at path into memory and returns
+// a MappedTree backed by that buffer. Windows has no syscall.Mmap, so
+// unlike the Unix build this does not share a read-only mapping across
+// processes; Close is a no-op since there is no mapping to release.
+func NewMappedTreeFromFile(path string) (*MappedTree, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMappedTree(data)
+}
@@ -0,0 +1,71 @@
+package mafsa
+
+import "sort"
+
+// NormalizedBuildTree wraps a BuildTree so every key runs through
+// Normalizer first, keeping normalization out of callers' hands and
+// out of sync with query time impossible by construction - pair it
+// with a NormalizedMinTree (or an Encoder/Decoder sharing the same
+// Normalizer) built from the same one.
+//
+// Insert cannot forward straight to the underlying BuildTree: BuildTree
+// requires keys in lexicographical order, but normalizing a key (for
+// example case-folding it) can change its relative order. Insert
+// instead buffers normalized keys and Finish sorts and inserts them
+// once the full key set is known.
+type NormalizedBuildTree struct {
+	Tree       *BuildTree
+	Normalizer Normalizer
+
+	keys []string
+}
+
+// NewNormalizedBuildTree wraps t, an empty BuildTree, so Finish inserts
+// keys normalized through n, in sorted order.
+func NewNormalizedBuildTree(t *BuildTree, n Normalizer) *NormalizedBuildTree {
+	return &NormalizedBuildTree{Tree: t, Normalizer: n}
+}
+
+// Insert normalizes key and buffers it; it is not added to the
+// underlying BuildTree until Finish is called.
+func (n *NormalizedBuildTree) Insert(key string) error {
+	n.keys = append(n.keys, n.Normalizer.Normalize(key))
+	return nil
+}
+
+// Finish sorts every buffered, normalized key and inserts them into
+// the underlying BuildTree in that order, satisfying BuildTree's
+// lexicographical-insertion requirement regardless of the order Insert
+// was originally called in. BuildTree itself needs no separate
+// finalizing pass before it is encoded, so n.Tree is ready for an
+// Encoder as soon as Finish returns.
+func (n *NormalizedBuildTree) Finish() error {
+	sort.Strings(n.keys)
+	for i, key := range n.keys {
+		if i > 0 && key == n.keys[i-1] {
+			continue
+		}
+		if err := n.Tree.Insert(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizedMinTree wraps a MinTree so Contains normalizes its
+// argument through the same Normalizer the tree was built with.
+type NormalizedMinTree struct {
+	Tree       *MinTree
+	Normalizer Normalizer
+}
+
+// NewNormalizedMinTree wraps t so Contains normalizes queries through
+// n before looking them up.
+func NewNormalizedMinTree(t *MinTree, n Normalizer) *NormalizedMinTree {
+	return &NormalizedMinTree{Tree: t, Normalizer: n}
+}
+
+// Contains reports whether word, once normalized, exists in the tree.
+func (n *NormalizedMinTree) Contains(word string) bool {
+	return n.Tree.Contains(n.Normalizer.Normalize(word))
+}
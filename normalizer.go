@@ -0,0 +1,74 @@
+package mafsa
+
+import (
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalizer lets callers run every key through a consistent
+// transformation before it reaches the tree, so that e.g. an NFC-
+// composed "café" matches an NFD-decomposed one, or so case variants
+// match without the caller lowercasing everything by hand. Because
+// normalization must be identical at build and query time, its ID is
+// persisted in the file header; see Encoder.Normalizer and
+// Decoder.Normalizer.
+type Normalizer interface {
+	// Normalize returns s transformed into this Normalizer's
+	// canonical form.
+	Normalize(s string) string
+
+	// ID uniquely identifies this Normalizer's scheme so it can be
+	// persisted in the file header. Never reuse an ID for a
+	// Normalizer with different behavior - doing so would let a
+	// Decoder silently accept a file built under a different scheme.
+	ID() byte
+}
+
+const (
+	normalizerNone byte = 0
+	normalizerNFC  byte = 1
+	normalizerNFKC byte = 2
+	normalizerFold byte = 3
+)
+
+// NFCNormalizer normalizes to NFC (canonical composition), so a
+// combining-mark-decomposed "é" matches a precomposed one.
+type NFCNormalizer struct{}
+
+// Normalize returns s in NFC form.
+func (NFCNormalizer) Normalize(s string) string { return norm.NFC.String(s) }
+
+// ID returns the persisted identifier for NFCNormalizer.
+func (NFCNormalizer) ID() byte { return normalizerNFC }
+
+// NFKCNormalizer normalizes to NFKC (compatibility composition), which
+// additionally folds compatibility variants - e.g. full-width digits -
+// onto their canonical equivalents.
+type NFKCNormalizer struct{}
+
+// Normalize returns s in NFKC form.
+func (NFKCNormalizer) Normalize(s string) string { return norm.NFKC.String(s) }
+
+// ID returns the persisted identifier for NFKCNormalizer.
+func (NFKCNormalizer) ID() byte { return normalizerNFKC }
+
+// FoldNormalizer case-folds s so callers no longer need to lowercase
+// input themselves.
+type FoldNormalizer struct{}
+
+// Normalize returns s case-folded under the root locale.
+func (FoldNormalizer) Normalize(s string) string {
+	return cases.Fold().String(s)
+}
+
+// ID returns the persisted identifier for FoldNormalizer.
+func (FoldNormalizer) ID() byte { return normalizerFold }
+
+// normalizerID returns n's persisted identifier, or normalizerNone if
+// n is nil.
+func normalizerID(n Normalizer) byte {
+	if n == nil {
+		return normalizerNone
+	}
+	return n.ID()
+}
@@ -0,0 +1,30 @@
+package mafsa
+
+import "testing"
+
+// buildMinTree inserts keys (already in sorted order, as BuildTree
+// requires) into a fresh BuildTree and round-trips it through Encoder
+// and Decoder to produce the resulting MinTree, the same path every
+// real caller takes to get from a BuildTree to a MinTree.
+func buildMinTree(t *testing.T, keys []string) *MinTree {
+	t.Helper()
+
+	bt := NewBuildTree()
+	for _, key := range keys {
+		if err := bt.Insert(key); err != nil {
+			t.Fatalf("Insert(%q): %v", key, err)
+		}
+	}
+
+	data, err := (&Encoder{Version: FormatV2}).Encode(bt)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	tree, err := new(Decoder).Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	return tree
+}
@@ -0,0 +1,143 @@
+package mafsa
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// BuildTransducer pairs a BuildTree with a uint64 value per key, ready
+// for minimization into a MinTransducer. Rather than the classic Mohri
+// construction, which pushes output weights onto edges and re-derives
+// them on every Insert, BuildTransducer leans on the minimal perfect
+// hash index Finish already needs to compute (see MinTree.WordAtIndex
+// and indexOf): once every key is inserted and the tree is minimized,
+// each key's rank becomes the index into a parallel value slice. That
+// trades away incremental lookups mid-build for keeping payload
+// storage entirely out of the edge format - the key set must be known
+// up front, exactly as BuildTree.Insert already requires keys in
+// sorted order.
+type BuildTransducer struct {
+	Tree   *BuildTree
+	values map[string]uint64
+}
+
+// NewBuildTransducer wraps an empty BuildTree t in a BuildTransducer.
+func NewBuildTransducer(t *BuildTree) *BuildTransducer {
+	return &BuildTransducer{
+		Tree:   t,
+		values: make(map[string]uint64),
+	}
+}
+
+// Insert adds key to the underlying BuildTree and records value to be
+// attached to it once Finish minimizes the tree.
+func (b *BuildTransducer) Insert(key string, value uint64) error {
+	if err := b.Tree.Insert(key); err != nil {
+		return err
+	}
+	b.values[key] = value
+	return nil
+}
+
+// Finish minimizes b's BuildTree the same way callers already do for a
+// plain BuildTree - round-tripping it through Encoder and Decoder, with
+// no separate finalizing pass on the BuildTree itself required first -
+// then reassembles the per-key values into perfect-hash rank order and
+// appends them to the encoded tree, producing a single on-disk blob a
+// MinTransducer can be loaded back from.
+//
+// The tree is written as FormatV2: FormatV1's header does not
+// round-trip (see decoder.go's v1 ptrLen/wordLen mismatch), so a v1
+// Finish can never succeed.
+func (b *BuildTransducer) Finish() (*MinTransducer, error) {
+	treeData, err := (&Encoder{Version: FormatV2}).Encode(b.Tree)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := new(Decoder).Decode(treeData)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]uint64, tree.Root.Number)
+	for key, value := range b.values {
+		index, final, ok := indexOf(tree, []rune(key))
+		if !ok || !final {
+			return nil, fmt.Errorf("mafsa: key %q not found after minimization", key)
+		}
+		values[index] = value
+	}
+
+	data := appendValues(treeData, values)
+
+	return &MinTransducer{Tree: tree, Values: values, Data: data}, nil
+}
+
+// appendValues builds the on-disk blob LoadMinTransducer expects: the
+// encoded tree, followed by one big-endian uint64 per value in
+// perfect-hash rank order, followed by an 8-byte big-endian trailer
+// giving the length of the tree portion so a loader can find the split
+// without re-parsing the tree itself.
+func appendValues(treeData []byte, values []uint64) []byte {
+	data := make([]byte, len(treeData)+8*len(values)+8)
+	n := copy(data, treeData)
+	for _, v := range values {
+		binary.BigEndian.PutUint64(data[n:], v)
+		n += 8
+	}
+	binary.BigEndian.PutUint64(data[n:], uint64(len(treeData)))
+	return data
+}
+
+// MinTransducer is a read-only, minimized key-value MA-FSA: a MinTree
+// of keys plus a value per key, indexed by the key's minimal perfect
+// hash rank. Data, if set, is the on-disk blob Finish/LoadMinTransducer
+// produced it from.
+type MinTransducer struct {
+	Tree   *MinTree
+	Values []uint64
+	Data   []byte
+}
+
+// Get returns the value associated with key and whether key exists in
+// the transducer.
+func (m *MinTransducer) Get(key string) (uint64, bool) {
+	index, final, ok := indexOf(m.Tree, []rune(key))
+	if !ok || !final {
+		return 0, false
+	}
+	return m.Values[index], true
+}
+
+// LoadMinTransducer decodes a blob previously produced by
+// BuildTransducer.Finish: an encoded tree, one uint64 per key in
+// perfect-hash rank order, and an 8-byte trailer naming the tree
+// portion's length.
+func LoadMinTransducer(data []byte) (*MinTransducer, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("mafsa: not enough bytes for a MinTransducer")
+	}
+
+	treeLen := int(binary.BigEndian.Uint64(data[len(data)-8:]))
+	if treeLen < 0 || treeLen > len(data)-8 {
+		return nil, fmt.Errorf("mafsa: invalid tree length %d in MinTransducer trailer", treeLen)
+	}
+	treeData := data[:treeLen]
+	valuesData := data[treeLen : len(data)-8]
+	if len(valuesData)%8 != 0 {
+		return nil, fmt.Errorf("mafsa: MinTransducer value section is not a whole number of uint64s")
+	}
+
+	tree, err := new(Decoder).Decode(treeData)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]uint64, len(valuesData)/8)
+	for i := range values {
+		values[i] = binary.BigEndian.Uint64(valuesData[i*8:])
+	}
+
+	return &MinTransducer{Tree: tree, Values: values, Data: data}, nil
+}
@@ -0,0 +1,52 @@
+package mafsa
+
+import "testing"
+
+// TestBuildTransducerPrefixKeys covers the case that previously broke
+// the key->value mapping: "a" is a proper prefix of "ab", which used to
+// collide with it on the same perfect-hash index (see the indexOf fix
+// in unrank.go), silently losing one key's value and leaving another
+// slot at its zero value.
+func TestBuildTransducerPrefixKeys(t *testing.T) {
+	want := map[string]uint64{
+		"a":   1,
+		"ab":  2,
+		"an":  3,
+		"and": 4,
+	}
+
+	bt := NewBuildTree()
+	tx := NewBuildTransducer(bt)
+	for _, key := range []string{"a", "ab", "an", "and"} {
+		if err := tx.Insert(key, want[key]); err != nil {
+			t.Fatalf("Insert(%q): %v", key, err)
+		}
+	}
+
+	min, err := tx.Finish()
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+
+	for key, value := range want {
+		got, ok := min.Get(key)
+		if !ok {
+			t.Errorf("Get(%q): not found", key)
+			continue
+		}
+		if got != value {
+			t.Errorf("Get(%q) = %d, want %d", key, got, value)
+		}
+	}
+
+	loaded, err := LoadMinTransducer(min.Data)
+	if err != nil {
+		t.Fatalf("LoadMinTransducer: %v", err)
+	}
+	for key, value := range want {
+		got, ok := loaded.Get(key)
+		if !ok || got != value {
+			t.Errorf("after LoadMinTransducer, Get(%q) = (%d, %v), want (%d, true)", key, got, ok, value)
+		}
+	}
+}
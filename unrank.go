@@ -0,0 +1,94 @@
+package mafsa
+
+// WordAtIndex returns the word at the given index in the minimal
+// perfect hash ordering established during decoding (see
+// Decoder.doNumbers), along with whether an entry exists at that
+// index. It is the inverse of indexOf: where indexOf turns a word into
+// its index, WordAtIndex turns an index back into its word.
+func (t *MinTree) WordAtIndex(i int) (string, bool) {
+	runes, ok := t.RuneAtIndex(i)
+	if !ok {
+		return "", false
+	}
+	return string(runes), true
+}
+
+// RuneAtIndex is the []rune variant of WordAtIndex.
+func (t *MinTree) RuneAtIndex(i int) ([]rune, bool) {
+	if i < 0 || i >= t.Root.Number {
+		return nil, false
+	}
+	return unrank(t.Root, i, nil)
+}
+
+// unrank walks node's outgoing edges in sorted rune order, subtracting
+// each child's contribution to node's Number until the remainder
+// falls within a child's subtree (or i reaches 0 at a final edge, in
+// which case prefix plus that edge's rune is the answer).
+func unrank(node *MinTreeNode, i int, prefix []rune) ([]rune, bool) {
+	for _, r := range sortKeys(node.Edges) {
+		child := node.Edges[r]
+		count := child.Number + boolToInt(child.Final)
+		if i >= count {
+			i -= count
+			continue
+		}
+
+		word := append(prefix, r)
+		if child.Final {
+			if i == 0 {
+				return word, true
+			}
+			i--
+		}
+		return unrank(child, i, word)
+	}
+
+	return nil, false
+}
+
+// indexOf walks letters from Root, accumulating the minimal perfect
+// hash index established by doNumbers along the way. It is the forward
+// direction of RuneAtIndex/WordAtIndex: rank instead of unrank. It is a
+// free function, not a MinTree method, since MinTree already exposes an
+// IndexedTraverse with a different signature. ok is false if letters
+// does not name a path through the tree; final reports whether that
+// path ends on a word.
+func indexOf(t *MinTree, letters []rune) (index int, final bool, ok bool) {
+	node := t.Root
+	for pos, r := range letters {
+		child, exists := node.Edges[r]
+		if !exists {
+			return 0, false, false
+		}
+
+		for _, sibling := range sortKeys(node.Edges) {
+			if sibling == r {
+				break
+			}
+			s := node.Edges[sibling]
+			index += s.Number + boolToInt(s.Final)
+		}
+
+		final = child.Final
+		if pos < len(letters)-1 {
+			// child.Final means some shorter word ends here; unrank
+			// counts it (i--) before descending into child's own
+			// subtree, so it must be counted here too, or a proper
+			// prefix key and a key below it in the tree collide on
+			// the same index.
+			if child.Final {
+				index++
+			}
+			node = child
+		}
+	}
+	return index, final, true
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package mafsa
+
+import "testing"
+
+// TestIndexOfRoundTrip exercises a dictionary where one word is a
+// proper prefix of another ("a" of "ab", "an" of "and"), which is
+// exactly the case indexOf previously miscounted: it omitted the +1 for
+// a proper-prefix word that unrank/WordAtIndex already count, so two
+// keys collided on the same index and one index was never produced.
+func TestIndexOfRoundTrip(t *testing.T) {
+	keys := []string{"a", "ab", "an", "and"}
+	tree := buildMinTree(t, keys)
+
+	if got := tree.Root.Number; got != len(keys) {
+		t.Fatalf("Root.Number = %d, want %d", got, len(keys))
+	}
+
+	seen := make(map[int]string)
+	for i := 0; i < tree.Root.Number; i++ {
+		word, ok := tree.WordAtIndex(i)
+		if !ok {
+			t.Fatalf("WordAtIndex(%d): no entry", i)
+		}
+		if other, dup := seen[i]; dup {
+			t.Fatalf("index %d produced by both %q and %q", i, other, word)
+		}
+		seen[i] = word
+
+		index, final, ok := indexOf(tree, []rune(word))
+		if !ok || !final {
+			t.Fatalf("indexOf(%q) = (_, %v, %v), want final and ok", word, final, ok)
+		}
+		if index != i {
+			t.Errorf("indexOf(%q) = %d, want %d (the index WordAtIndex produced it from)", word, index, i)
+		}
+	}
+}
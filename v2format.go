@@ -0,0 +1,43 @@
+package mafsa
+
+// Version identifies the on-disk encoding written by Encoder and
+// understood by Decoder. FormatV1 is the original fixed-width
+// encoding; FormatV2 trades a little encode-time CPU for
+// self-delimiting UTF-8 characters and varint-delta pointers, which
+// typically cuts file size 40-60% on real word lists.
+type Version byte
+
+const (
+	FormatV1 Version = 1
+	FormatV2 Version = 2
+)
+
+// v2HeaderLen is the number of prolog bytes written before the root
+// node's edges in a v2 file: the version byte and the Normalizer ID
+// byte.
+const v2HeaderLen = 2
+
+// zigzagEncode maps a signed delta to an unsigned value so that small
+// deltas stay small in either direction. It's needed because a v2
+// pointer is a delta from the edge that holds it, and a shared suffix
+// can legitimately point to a target earlier in the file than the
+// edge referencing it.
+func zigzagEncode(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+// zigzagDecode is the inverse of zigzagEncode.
+func zigzagDecode(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// uvarintLen returns the number of bytes binary.PutUvarint would use
+// to encode v, without actually encoding it.
+func uvarintLen(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+	return n
+}